@@ -0,0 +1,104 @@
+package gowatch
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+	"time"
+)
+
+//shutdownGracePeriod time to wait for a child process group to exit after
+//SIGTERM before it is forcefully killed with SIGKILL
+const shutdownGracePeriod = 5 * time.Second
+
+//App interface to compile, start and stop the watched program. Watcher
+//itself drives rebuilds by calling Compile/Stop/Start in sequence (see
+//Watcher.restart), not a single Restart step, so implementations only need
+//these three.
+type App interface {
+	Compile() error
+	Start() (*exec.Cmd, error)
+	Stop(cmd *exec.Cmd) error
+}
+
+//AppRunner default App implementation, builds a Go program with `go build`
+//(or buildCommand when set) and runs the resulting binary
+type AppRunner struct {
+	dir        string
+	runFlags   []string
+	buildFlags []string
+	binaryName string
+
+	//buildCommand overrides the default `go build` invocation, e.g. to run
+	//`go generate` or a frontend asset pipeline before compiling
+	buildCommand []string
+
+	//env extra KEY=VALUE pairs merged on top of the current process
+	//environment for both the build and run commands
+	env map[string]string
+}
+
+func (a AppRunner) binaryPath() string {
+	return filepath.Join(a.dir, a.binaryName)
+}
+
+//Compile builds the watched program, running buildCommand if set or
+//`go build` otherwise
+func (a AppRunner) Compile() error {
+	var cmd *exec.Cmd
+	if len(a.buildCommand) > 0 {
+		cmd = exec.Command(a.buildCommand[0], a.buildCommand[1:]...)
+	} else {
+		args := append([]string{"build", "-o", a.binaryName}, a.buildFlags...)
+		cmd = exec.Command("go", args...)
+	}
+	cmd.Dir = a.dir
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = mergeEnv(a.env)
+	if err := cmd.Run(); err != nil {
+		return ErrCmdCompile
+	}
+	return nil
+}
+
+//Start runs the compiled binary in its own process group, so the whole
+//process tree it spawns can be terminated together
+func (a AppRunner) Start() (*exec.Cmd, error) {
+	cmd := exec.Command(a.binaryPath(), a.runFlags...)
+	cmd.Dir = a.dir
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = mergeEnv(a.env)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	return cmd, nil
+}
+
+//Stop terminates cmd's process group, sending SIGTERM first and escalating
+//to SIGKILL if it doesn't exit within shutdownGracePeriod
+func (a AppRunner) Stop(cmd *exec.Cmd) error {
+	if cmd == nil || cmd.Process == nil {
+		return nil
+	}
+	pgid, err := syscall.Getpgid(cmd.Process.Pid)
+	if err != nil {
+		return cmd.Process.Kill()
+	}
+	if err := syscall.Kill(-pgid, syscall.SIGTERM); err != nil {
+		return err
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+
+	select {
+	case <-done:
+		return nil
+	case <-time.After(shutdownGracePeriod):
+		return syscall.Kill(-pgid, syscall.SIGKILL)
+	}
+}
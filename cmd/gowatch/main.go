@@ -0,0 +1,68 @@
+// Command gowatch watches a Go project for changes and rebuilds/restarts it.
+package main
+
+import (
+	"flag"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/msAlcantara/gowatch"
+)
+
+func main() {
+	dir := flag.String("dir", ".", "directory to watch")
+	debounce := flag.Duration("debounce", 0, "quiet period to coalesce a burst of events into a single restart (overrides .gowatch.yaml)")
+	profile := flag.String("profile", "", "named profile from .gowatch.yaml to apply")
+	ignore := flag.String("ignore", "", "comma-separated glob patterns of files to not watch (overrides .gowatch.yaml)")
+	includeExt := flag.String("include-ext", "", "comma-separated file extensions that trigger a restart (overrides .gowatch.yaml)")
+	buildFlags := flag.String("build-flags", "", "space-separated flags passed to go build, ignored when build_command is set (overrides .gowatch.yaml)")
+	runFlags := flag.String("run-flags", "", "space-separated flags passed to the compiled binary (overrides .gowatch.yaml)")
+	liveReloadPort := flag.Int("livereload-port", 0, "port to serve LiveReload on, 0 disables it (overrides .gowatch.yaml)")
+	flag.Parse()
+
+	cfg, err := gowatch.LoadConfig(*dir)
+	if err != nil {
+		logrus.Fatalf("gowatch: loading .gowatch.yaml: %v", err)
+	}
+	cfg = cfg.Profile(*profile)
+
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "debounce":
+			cfg.Debounce = *debounce
+		case "ignore":
+			cfg.Ignore = splitNonEmpty(*ignore, ",")
+		case "include-ext":
+			cfg.IncludeExt = splitNonEmpty(*includeExt, ",")
+		case "build-flags":
+			cfg.BuildFlags = splitNonEmpty(*buildFlags, " ")
+		case "run-flags":
+			cfg.RunFlags = splitNonEmpty(*runFlags, " ")
+		case "livereload-port":
+			cfg.LiveReloadPort = *liveReloadPort
+		}
+	})
+
+	w, err := gowatch.NewWatcher(*dir, cfg)
+	if err != nil {
+		logrus.Fatalf("gowatch: %v", err)
+	}
+	if err := w.Run(); err != nil {
+		logrus.Fatalf("gowatch: %v", err)
+	}
+}
+
+//splitNonEmpty splits s on sep, dropping empty fields. An empty s yields nil.
+func splitNonEmpty(s, sep string) []string {
+	if s == "" {
+		return nil
+	}
+	var out []string
+	for _, part := range strings.Split(s, sep) {
+		if part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
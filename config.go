@@ -0,0 +1,160 @@
+package gowatch
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"gopkg.in/yaml.v2"
+)
+
+//configFileName name of the structured config file discovered by walking up from the watched directory
+const configFileName = ".gowatch.yaml"
+
+//defaultIncludeExt file extensions that trigger a restart when IncludeExt is not set
+var defaultIncludeExt = []string{".go"}
+
+//Config structured options for a Watcher, loadable from .gowatch.yaml and
+//overridable by CLI flags. Fields tagged `yaml:"-"` are programmatic only
+//and cannot come from the file.
+type Config struct {
+	BuildFlags     []string          `yaml:"build_flags"`
+	RunFlags       []string          `yaml:"run_flags"`
+	Ignore         []string          `yaml:"ignore"`
+	IncludeExt     []string          `yaml:"include_ext"`
+	Debounce       time.Duration     `yaml:"debounce"`
+	PreBuild       string            `yaml:"pre_build"`
+	Env            map[string]string `yaml:"env"`
+	BuildCommand   []string          `yaml:"build_command"`
+	LiveReloadPort int               `yaml:"livereload_port"`
+	Profiles       map[string]Config `yaml:"profiles"`
+
+	//Hooks programmatic build/restart callbacks, not loadable from the config file
+	Hooks Hooks `yaml:"-"`
+}
+
+//LoadConfig discovers configFileName by walking up from dir and parses it.
+//It returns a zero Config (and no error) when no config file is found.
+func LoadConfig(dir string) (Config, error) {
+	path, err := findConfigFile(dir)
+	if err != nil {
+		return Config{}, err
+	}
+	if path == "" {
+		return Config{}, nil
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+//findConfigFile walks up from dir looking for configFileName, returning ""
+//when none is found
+func findConfigFile(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for {
+		candidate := filepath.Join(dir, configFileName)
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+//Profile merges the named profile over c, with any field the profile leaves
+//zero falling back to c's value. An unknown or empty name returns c unchanged.
+func (c Config) Profile(name string) Config {
+	profile, ok := c.Profiles[name]
+	if name == "" || !ok {
+		return c
+	}
+
+	merged := c
+	merged.Profiles = nil
+	if len(profile.BuildFlags) > 0 {
+		merged.BuildFlags = profile.BuildFlags
+	}
+	if len(profile.RunFlags) > 0 {
+		merged.RunFlags = profile.RunFlags
+	}
+	if len(profile.Ignore) > 0 {
+		merged.Ignore = profile.Ignore
+	}
+	if len(profile.IncludeExt) > 0 {
+		merged.IncludeExt = profile.IncludeExt
+	}
+	if profile.Debounce > 0 {
+		merged.Debounce = profile.Debounce
+	}
+	if profile.PreBuild != "" {
+		merged.PreBuild = profile.PreBuild
+	}
+	if len(profile.Env) > 0 {
+		merged.Env = profile.Env
+	}
+	if len(profile.BuildCommand) > 0 {
+		merged.BuildCommand = profile.BuildCommand
+	}
+	if profile.LiveReloadPort != 0 {
+		merged.LiveReloadPort = profile.LiveReloadPort
+	}
+	return merged
+}
+
+//hooks returns cfg.Hooks with PreBuild wrapped to also run the pre_build
+//shell command, if one is configured
+func (cfg Config) hooks(dir string) Hooks {
+	hooks := cfg.Hooks
+	if cfg.PreBuild == "" {
+		return hooks
+	}
+	userPreBuild := hooks.PreBuild
+	hooks.PreBuild = func(event fsnotify.Event) error {
+		if err := runShellCommand(cfg.PreBuild, dir, cfg.Env); err != nil {
+			return err
+		}
+		if userPreBuild != nil {
+			return userPreBuild(event)
+		}
+		return nil
+	}
+	return hooks
+}
+
+//runShellCommand runs command in dir through `sh -c`, with env merged on top
+//of the current process environment
+func runShellCommand(command, dir string, env map[string]string) error {
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Dir = dir
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = mergeEnv(env)
+	return cmd.Run()
+}
+
+//mergeEnv appends overrides, formatted as KEY=VALUE, on top of the current
+//process environment
+func mergeEnv(overrides map[string]string) []string {
+	env := os.Environ()
+	for k, v := range overrides {
+		env = append(env, k+"="+v)
+	}
+	return env
+}
@@ -0,0 +1,211 @@
+package gowatch
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestProfileMergesEachFieldIndependently(t *testing.T) {
+	base := Config{
+		BuildFlags:     []string{"base-build"},
+		RunFlags:       []string{"base-run"},
+		Ignore:         []string{"base-ignore"},
+		IncludeExt:     []string{".base"},
+		Debounce:       100 * time.Millisecond,
+		PreBuild:       "base-pre-build",
+		Env:            map[string]string{"BASE": "1"},
+		BuildCommand:   []string{"base-cmd"},
+		LiveReloadPort: 1111,
+		Profiles: map[string]Config{
+			"web": {
+				BuildFlags:     []string{"web-build"},
+				RunFlags:       []string{"web-run"},
+				Ignore:         []string{"web-ignore"},
+				IncludeExt:     []string{".web"},
+				Debounce:       200 * time.Millisecond,
+				PreBuild:       "web-pre-build",
+				Env:            map[string]string{"WEB": "1"},
+				BuildCommand:   []string{"web-cmd"},
+				LiveReloadPort: 2222,
+			},
+		},
+	}
+
+	merged := base.Profile("web")
+
+	want := base.Profiles["web"]
+	if s := merged.BuildFlags; len(s) != 1 || s[0] != want.BuildFlags[0] {
+		t.Errorf("BuildFlags = %v, want %v", s, want.BuildFlags)
+	}
+	if s := merged.RunFlags; len(s) != 1 || s[0] != want.RunFlags[0] {
+		t.Errorf("RunFlags = %v, want %v", s, want.RunFlags)
+	}
+	if s := merged.Ignore; len(s) != 1 || s[0] != want.Ignore[0] {
+		t.Errorf("Ignore = %v, want %v", s, want.Ignore)
+	}
+	if s := merged.IncludeExt; len(s) != 1 || s[0] != want.IncludeExt[0] {
+		t.Errorf("IncludeExt = %v, want %v", s, want.IncludeExt)
+	}
+	if merged.Debounce != want.Debounce {
+		t.Errorf("Debounce = %v, want %v", merged.Debounce, want.Debounce)
+	}
+	if merged.PreBuild != want.PreBuild {
+		t.Errorf("PreBuild = %q, want %q", merged.PreBuild, want.PreBuild)
+	}
+	if merged.Env["WEB"] != "1" {
+		t.Errorf("Env = %v, want %v", merged.Env, want.Env)
+	}
+	if s := merged.BuildCommand; len(s) != 1 || s[0] != want.BuildCommand[0] {
+		t.Errorf("BuildCommand = %v, want %v", s, want.BuildCommand)
+	}
+	if merged.LiveReloadPort != want.LiveReloadPort {
+		t.Errorf("LiveReloadPort = %v, want %v", merged.LiveReloadPort, want.LiveReloadPort)
+	}
+	if merged.Profiles != nil {
+		t.Errorf("Profiles = %v, want nil", merged.Profiles)
+	}
+}
+
+func TestProfileFallsBackToBaseForFieldsTheProfileLeavesUnset(t *testing.T) {
+	base := Config{
+		BuildFlags:     []string{"base-build"},
+		Debounce:       100 * time.Millisecond,
+		LiveReloadPort: 1111,
+		Profiles: map[string]Config{
+			"web": {RunFlags: []string{"web-run"}},
+		},
+	}
+
+	merged := base.Profile("web")
+
+	if s := merged.BuildFlags; len(s) != 1 || s[0] != "base-build" {
+		t.Errorf("BuildFlags = %v, want base value to survive an unset profile field", s)
+	}
+	if merged.Debounce != 100*time.Millisecond {
+		t.Errorf("Debounce = %v, want base value to survive an unset profile field", merged.Debounce)
+	}
+	if merged.LiveReloadPort != 1111 {
+		t.Errorf("LiveReloadPort = %v, want base value to survive an unset profile field", merged.LiveReloadPort)
+	}
+	if s := merged.RunFlags; len(s) != 1 || s[0] != "web-run" {
+		t.Errorf("RunFlags = %v, want the profile's value", s)
+	}
+}
+
+func TestProfileReturnsBaseUnchangedForUnknownOrEmptyName(t *testing.T) {
+	base := Config{BuildFlags: []string{"base-build"}}
+
+	if merged := base.Profile(""); len(merged.BuildFlags) != 1 || merged.BuildFlags[0] != "base-build" {
+		t.Fatalf("empty profile name: got %v, want base unchanged", merged.BuildFlags)
+	}
+	if merged := base.Profile("does-not-exist"); len(merged.BuildFlags) != 1 || merged.BuildFlags[0] != "base-build" {
+		t.Fatalf("unknown profile name: got %v, want base unchanged", merged.BuildFlags)
+	}
+}
+
+func TestFindConfigFileStopsAtFilesystemRoot(t *testing.T) {
+	dir := t.TempDir()
+	nested := filepath.Join(dir, "a", "b", "c")
+	if err := os.MkdirAll(nested, 0o755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+
+	path, err := findConfigFile(nested)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if path != "" {
+		t.Fatalf("expected no config file to be found, got %q", path)
+	}
+}
+
+func TestFindConfigFileWalksUpToAnAncestor(t *testing.T) {
+	dir := t.TempDir()
+	nested := filepath.Join(dir, "a", "b")
+	if err := os.MkdirAll(nested, 0o755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	want := filepath.Join(dir, configFileName)
+	if err := os.WriteFile(want, []byte("debounce: 100ms\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	path, err := findConfigFile(nested)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if path != want {
+		t.Fatalf("findConfigFile = %q, want %q", path, want)
+	}
+}
+
+func TestLoadConfigRoundTripsGowatchYaml(t *testing.T) {
+	dir := t.TempDir()
+	yaml := `
+build_flags: ["-race"]
+run_flags: ["--verbose"]
+ignore: ["*.tmp"]
+include_ext: [".go", ".tmpl"]
+debounce: 300ms
+pre_build: "echo hi"
+env:
+  FOO: bar
+livereload_port: 35729
+profiles:
+  web:
+    run_flags: ["--port=8080"]
+    include_ext: [".go", ".tmpl", ".html"]
+`
+	path := filepath.Join(dir, configFileName)
+	if err := os.WriteFile(path, []byte(yaml), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	cfg, err := LoadConfig(dir)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+
+	if len(cfg.BuildFlags) != 1 || cfg.BuildFlags[0] != "-race" {
+		t.Errorf("BuildFlags = %v", cfg.BuildFlags)
+	}
+	if cfg.Debounce != 300*time.Millisecond {
+		t.Errorf("Debounce = %v", cfg.Debounce)
+	}
+	if cfg.Env["FOO"] != "bar" {
+		t.Errorf("Env = %v", cfg.Env)
+	}
+	if cfg.LiveReloadPort != 35729 {
+		t.Errorf("LiveReloadPort = %v", cfg.LiveReloadPort)
+	}
+
+	web, ok := cfg.Profiles["web"]
+	if !ok {
+		t.Fatalf("expected a %q profile, got %v", "web", cfg.Profiles)
+	}
+	if len(web.RunFlags) != 1 || web.RunFlags[0] != "--port=8080" {
+		t.Errorf("web.RunFlags = %v", web.RunFlags)
+	}
+
+	merged := cfg.Profile("web")
+	if len(merged.IncludeExt) != 3 || merged.IncludeExt[2] != ".html" {
+		t.Errorf("merged.IncludeExt = %v, want the web profile's value", merged.IncludeExt)
+	}
+	if len(merged.BuildFlags) != 1 || merged.BuildFlags[0] != "-race" {
+		t.Errorf("merged.BuildFlags = %v, want the base value to survive since web doesn't set it", merged.BuildFlags)
+	}
+}
+
+func TestLoadConfigReturnsZeroValueWhenNoFileExists(t *testing.T) {
+	dir := t.TempDir()
+
+	cfg, err := LoadConfig(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.BuildFlags != nil || cfg.Debounce != 0 || cfg.Profiles != nil {
+		t.Fatalf("expected a zero Config, got %+v", cfg)
+	}
+}
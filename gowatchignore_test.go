@@ -0,0 +1,32 @@
+package gowatch
+
+import (
+	"path/filepath"
+	"testing"
+
+	gitignore "github.com/sabhiram/go-gitignore"
+)
+
+func TestIsToIgnoreFileMatchesAnchoredGowatchignorePattern(t *testing.T) {
+	dir := "/home/user/project"
+	gi := gitignore.CompileIgnoreLines("/vendor")
+	w := Watcher{dir: dir, gowatchignore: gi}
+
+	ignored, err := w.isToIgnoreFile(filepath.Join(dir, "vendor", "lib.go"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !ignored {
+		t.Fatalf("expected an anchored .gowatchignore pattern to match a top-level dir under w.dir")
+	}
+}
+
+func TestShouldSkipDirMatchesAnchoredGowatchignorePattern(t *testing.T) {
+	dir := "/home/user/project"
+	gi := gitignore.CompileIgnoreLines("/generated")
+	w := Watcher{dir: dir, gowatchignore: gi}
+
+	if !w.shouldSkipDir(filepath.Join(dir, "generated")) {
+		t.Fatalf("expected an anchored .gowatchignore pattern to prune the matching top-level dir")
+	}
+}
@@ -0,0 +1,20 @@
+package gowatch
+
+import "github.com/fsnotify/fsnotify"
+
+//Hooks optional callbacks invoked around the build/run lifecycle, letting
+//callers drive non-Go build steps (go generate, templ generate, protobuf
+//compilation, Tailwind rebuilds, ...) or react to restarts
+type Hooks struct {
+	//PreBuild runs before the build command
+	PreBuild func(event fsnotify.Event) error
+
+	//PostBuild runs after a successful build
+	PostBuild func(event fsnotify.Event) error
+
+	//PreRun runs before the compiled binary is started
+	PreRun func(event fsnotify.Event) error
+
+	//OnRestart runs once a restart has completed
+	OnRestart func(event fsnotify.Event) error
+}
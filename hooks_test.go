@@ -0,0 +1,66 @@
+package gowatch
+
+import (
+	"os/exec"
+	"reflect"
+	"testing"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+type fakeApp struct {
+	calls []string
+}
+
+func (a *fakeApp) Compile() error {
+	a.calls = append(a.calls, "Compile")
+	return nil
+}
+
+func (a *fakeApp) Start() (*exec.Cmd, error) {
+	a.calls = append(a.calls, "Start")
+	return &exec.Cmd{}, nil
+}
+
+func (a *fakeApp) Stop(cmd *exec.Cmd) error {
+	a.calls = append(a.calls, "Stop")
+	return nil
+}
+
+func TestRestartCallsPreRunHookBeforeStartingNewProcess(t *testing.T) {
+	app := &fakeApp{}
+	var hookCalls []string
+	var appCallsAtPreRun []string
+	w := Watcher{
+		app: app,
+		hooks: Hooks{
+			PreBuild:  func(fsnotify.Event) error { hookCalls = append(hookCalls, "PreBuild"); return nil },
+			PostBuild: func(fsnotify.Event) error { hookCalls = append(hookCalls, "PostBuild"); return nil },
+			PreRun: func(fsnotify.Event) error {
+				hookCalls = append(hookCalls, "PreRun")
+				appCallsAtPreRun = append([]string(nil), app.calls...)
+				return nil
+			},
+			OnRestart: func(fsnotify.Event) error { hookCalls = append(hookCalls, "OnRestart"); return nil },
+		},
+	}
+	cmd := &exec.Cmd{}
+
+	if err := w.restart(cmd, fsnotify.Event{Name: "main.go"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	wantHooks := []string{"PreBuild", "PostBuild", "PreRun", "OnRestart"}
+	if !reflect.DeepEqual(hookCalls, wantHooks) {
+		t.Fatalf("hooks fired in order %v, want %v", hookCalls, wantHooks)
+	}
+
+	if want := []string{"Compile"}; !reflect.DeepEqual(appCallsAtPreRun, want) {
+		t.Fatalf("app calls when PreRun fired were %v, want %v", appCallsAtPreRun, want)
+	}
+
+	wantAppCalls := []string{"Compile", "Stop", "Start"}
+	if !reflect.DeepEqual(app.calls, wantAppCalls) {
+		t.Fatalf("app calls were %v, want %v", app.calls, wantAppCalls)
+	}
+}
@@ -0,0 +1,139 @@
+// Package livereload implements a minimal server for the LiveReload v7
+// protocol (https://github.com/livereload/livereload-js) so browsers can be
+// reloaded automatically after gowatch restarts the watched program.
+package livereload
+
+import (
+	_ "embed"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"sync"
+
+	"github.com/gorilla/websocket"
+	"github.com/sirupsen/logrus"
+)
+
+//DefaultPort TCP port the server listens on when NewServer is given 0
+const DefaultPort = 35729
+
+//protocol LiveReload protocol version this server speaks
+const protocol = "http://livereload.com/protocols/official-7"
+
+//clientScript the official livereload-js client (assets/livereload.js, MIT
+//licensed, see assets/LICENSE), served directly so /livereload.js works offline
+//go:embed assets/livereload.js
+var clientScript []byte
+
+//Server serves the LiveReload WebSocket handshake and broadcasts reload commands
+type Server struct {
+	port     int
+	upgrader websocket.Upgrader
+
+	mu      sync.Mutex
+	clients map[*websocket.Conn]bool
+}
+
+//NewServer creates a Server listening on port. A port of 0 uses DefaultPort.
+func NewServer(port int) *Server {
+	if port == 0 {
+		port = DefaultPort
+	}
+	return &Server{
+		port:    port,
+		clients: map[*websocket.Conn]bool{},
+		upgrader: websocket.Upgrader{
+			CheckOrigin: func(r *http.Request) bool { return true },
+		},
+	}
+}
+
+//ListenAndServe starts the HTTP/WebSocket server. It blocks until the server stops.
+func (s *Server) ListenAndServe() error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/livereload.js", serveClientScript)
+	mux.HandleFunc("/livereload", s.serveWebSocket)
+	return http.ListenAndServe(fmt.Sprintf(":%d", s.port), mux)
+}
+
+func serveClientScript(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/javascript")
+	w.Write(clientScript)
+}
+
+func (s *Server) serveWebSocket(w http.ResponseWriter, r *http.Request) {
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		logrus.Debugf("livereload: upgrade failed: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	if err := s.handshake(conn); err != nil {
+		logrus.Debugf("livereload: handshake failed: %v", err)
+		return
+	}
+
+	s.addClient(conn)
+	defer s.removeClient(conn)
+
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			return
+		}
+	}
+}
+
+type helloMessage struct {
+	Command    string   `json:"command"`
+	Protocols  []string `json:"protocols"`
+	ServerName string   `json:"serverName"`
+}
+
+//handshake reads the client's hello command and replies with the server's own
+func (s *Server) handshake(conn *websocket.Conn) error {
+	if _, _, err := conn.ReadMessage(); err != nil {
+		return err
+	}
+	return conn.WriteJSON(helloMessage{
+		Command:    "hello",
+		Protocols:  []string{protocol},
+		ServerName: "gowatch",
+	})
+}
+
+func (s *Server) addClient(conn *websocket.Conn) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.clients[conn] = true
+}
+
+func (s *Server) removeClient(conn *websocket.Conn) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.clients, conn)
+}
+
+type reloadMessage struct {
+	Command string `json:"command"`
+	Path    string `json:"path"`
+	LiveCSS bool   `json:"liveCSS"`
+}
+
+//Reload broadcasts a reload command for path to every connected client. CSS
+//files reload in place (liveCSS); anything else triggers a full page reload.
+func (s *Server) Reload(path string) {
+	msg := reloadMessage{
+		Command: "reload",
+		Path:    path,
+		LiveCSS: filepath.Ext(path) == ".css",
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for conn := range s.clients {
+		if err := conn.WriteJSON(msg); err != nil {
+			logrus.Debugf("livereload: broadcast failed: %v", err)
+		}
+	}
+}
@@ -0,0 +1,20 @@
+package livereload
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestServeClientScriptServesEmbeddedFileDirectly(t *testing.T) {
+	req := httptest.NewRequest("GET", "/livereload.js", nil)
+	rec := httptest.NewRecorder()
+
+	serveClientScript(rec, req)
+
+	if rec.Code != 200 {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	if rec.Body.Len() == 0 {
+		t.Fatalf("expected the embedded livereload.js to be written to the response body")
+	}
+}
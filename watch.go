@@ -5,13 +5,28 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"os/signal"
 	"path/filepath"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/fsnotify/fsnotify"
+	gitignore "github.com/sabhiram/go-gitignore"
 	"github.com/sirupsen/logrus"
+
+	"github.com/msAlcantara/gowatch/livereload"
 )
 
+//defaultDebounce quiet period used when NewWatcher receives a zero debounce
+const defaultDebounce = 200 * time.Millisecond
+
+//gowatchignoreFile name of the optional gitignore-style file loaded from the watched directory
+const gowatchignoreFile = ".gowatchignore"
+
+//defaultSkipDirs directory names pruned from the recursive watch regardless of .gowatchignore
+var defaultSkipDirs = []string{"vendor", "node_modules", "dist", "build"}
+
 var (
 	//ErrCmdCompile go build command failed to compile program error
 	ErrCmdCompile = errors.New("error to compile program")
@@ -38,48 +53,140 @@ type Watcher struct {
 
 	//signal to stop watcher events
 	stop chan bool
+
+	//quiet period to coalesce a burst of events into a single restart
+	debounce time.Duration
+
+	//rules loaded from .gowatchignore, nil when the file is not present
+	gowatchignore *gitignore.GitIgnore
+
+	//file extensions that trigger a restart
+	includeExt []string
+
+	//optional callbacks invoked around the build/run lifecycle
+	hooks Hooks
+
+	//optional LiveReload server, nil when cfg.LiveReloadPort is 0
+	liveReload *livereload.Server
 }
 
-//NewWatcher create watcher struct with all values filled
-func NewWatcher(dir string, buildFlags, runFlags, ignore []string) (*Watcher, error) {
+//NewWatcher create watcher struct with all values filled from cfg. Callers
+//building cfg from both a .gowatch.yaml (via LoadConfig) and CLI flags should
+//apply the CLI values last so they take precedence.
+func NewWatcher(dir string, cfg Config) (*Watcher, error) {
 	watcher, err := fsnotify.NewWatcher()
 	if err != nil {
 		return nil, err
 	}
+
+	debounce := cfg.Debounce
+	if debounce <= 0 {
+		debounce = defaultDebounce
+	}
+
+	includeExt := cfg.IncludeExt
+	if len(includeExt) == 0 {
+		includeExt = defaultIncludeExt
+	}
+
+	gowatchignore, err := loadGowatchignore(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var liveReloadServer *livereload.Server
+	if cfg.LiveReloadPort != 0 {
+		liveReloadServer = livereload.NewServer(cfg.LiveReloadPort)
+	}
+
 	return &Watcher{
-		ignore:  ignore,
-		dir:     dir,
-		watcher: watcher,
-		stop:    make(chan bool),
+		ignore:        cfg.Ignore,
+		includeExt:    includeExt,
+		dir:           dir,
+		watcher:       watcher,
+		stop:          make(chan bool),
+		debounce:      debounce,
+		gowatchignore: gowatchignore,
+		hooks:         cfg.hooks(dir),
+		liveReload:    liveReloadServer,
 		app: AppRunner{
-			dir:        dir,
-			runFlags:   runFlags,
-			buildFlags: buildFlags,
-			binaryName: getCurrentFolderName(dir),
+			dir:          dir,
+			runFlags:     cfg.RunFlags,
+			buildFlags:   cfg.BuildFlags,
+			binaryName:   getCurrentFolderName(dir),
+			buildCommand: cfg.BuildCommand,
+			env:          cfg.Env,
 		},
 	}, nil
 }
 
-//Run start the watching for changes  in .go files
+//loadGowatchignore reads a .gowatchignore from dir, if present. It returns a
+//nil matcher (and no error) when the file does not exist.
+func loadGowatchignore(dir string) (*gitignore.GitIgnore, error) {
+	path := filepath.Join(dir, gowatchignoreFile)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil, nil
+	}
+	return gitignore.CompileIgnoreFile(path)
+}
+
+//Run start the watching for changes in files with one of the included
+//extensions (.go by default), stopping gracefully on SIGINT/SIGTERM
 func (w Watcher) Run() error {
+	if w.hooks.PreBuild != nil {
+		if err := w.hooks.PreBuild(fsnotify.Event{}); err != nil {
+			return err
+		}
+	}
 	if err := w.app.Compile(); err != nil {
 		return err
 	}
+	if w.hooks.PostBuild != nil {
+		if err := w.hooks.PostBuild(fsnotify.Event{}); err != nil {
+			return err
+		}
+	}
+	if w.hooks.PreRun != nil {
+		if err := w.hooks.PreRun(fsnotify.Event{}); err != nil {
+			return err
+		}
+	}
 	cmd, err := w.app.Start()
 	if err != nil {
 		return err
 	}
-	if err := w.start(cmd); err != nil {
-		if err := w.shutdown(); err != nil {
+
+	if w.liveReload != nil {
+		go func() {
+			if err := w.liveReload.ListenAndServe(); err != nil {
+				logrus.Debugf("livereload: server stopped: %v", err)
+			}
+		}()
+	}
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sig
+		logrus.Debug("received shutdown signal")
+		close(w.stop)
+	}()
+
+	runErr := w.start(cmd)
+	if runErr != nil && !errors.Is(runErr, ErrStopNotifyEvents) {
+		if err := w.shutdown(cmd); err != nil {
 			return fmt.Errorf("Error to shutdown: %v", err)
 		}
-		return err
+		return runErr
 	}
-	return nil
+	return w.shutdown(cmd)
 }
 
-func (w Watcher) shutdown() error {
+func (w Watcher) shutdown(cmd *exec.Cmd) error {
 	logrus.Debug("clean up...")
+	if err := w.app.Stop(cmd); err != nil {
+		return err
+	}
 	if w.watcher == nil {
 		return ErrInotifyNil
 	}
@@ -96,62 +203,104 @@ func (w Watcher) isToIgnoreFile(file string) (bool, error) {
 			return matched, nil
 		}
 	}
+	if w.gowatchignore != nil && w.gowatchignore.MatchesPath(w.relativeToDir(file)) {
+		return true, nil
+	}
 	return false, nil
 }
 
+//relativeToDir expresses path relative to w.dir, the directory a
+//.gowatchignore is loaded from, so anchored patterns (a leading "/") match
+//against the same root the file itself is written against. path is returned
+//unchanged if it cannot be made relative to w.dir.
+func (w Watcher) relativeToDir(path string) string {
+	rel, err := filepath.Rel(w.dir, path)
+	if err != nil {
+		return path
+	}
+	return rel
+}
+
+//isIncludedExt reports whether name has one of w.includeExt's extensions
+func (w Watcher) isIncludedExt(name string) bool {
+	return contains(w.includeExt, filepath.Ext(name))
+}
+
+// events reads fsnotify events, coalescing any burst of qualifying events
+// (Write/Rename of a file with one of w.includeExt's extensions) that arrives
+// within w.debounce into a single restart, deduplicated by filename. Create
+// events only add newly created directories to the watch.
 func (w *Watcher) events(cmd *exec.Cmd) error {
-	select {
+	pending := map[string]fsnotify.Event{}
+
+	timer := time.NewTimer(w.debounce)
+	if !timer.Stop() {
+		<-timer.C
+	}
+	defer timer.Stop()
 
-	case <-w.stop:
-		return ErrStopNotifyEvents
+	for {
+		select {
 
-	case event, ok := <-w.watcher.Events:
-		if !ok {
-			return nil
-		}
-		if event.Op&fsnotify.Create == fsnotify.Create {
-			newDirectories, err := discoverSubDirectories(event.Name)
-			if err != nil {
-				return err
+		case <-w.stop:
+			return ErrStopNotifyEvents
+
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return nil
 			}
-			logrus.Debugf("find new directories: %v\n", newDirectories)
-			if err := w.addDirectories(newDirectories...); err != nil {
+			if event.Op&fsnotify.Create == fsnotify.Create {
+				newDirectories, err := w.discoverSubDirectories(event.Name)
+				if err != nil {
+					return err
+				}
+				logrus.Debugf("find new directories: %v\n", newDirectories)
+				if err := w.addDirectories(newDirectories...); err != nil {
+					return err
+				}
+				continue
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Rename) == 0 {
+				continue
+			}
+			if !w.isIncludedExt(event.Name) {
+				continue
+			}
+			pending[event.Name] = event
+			timer.Reset(w.debounce)
+
+		case <-timer.C:
+			event, ok, err := w.qualifyingEvent(pending)
+			if err != nil {
 				return err
 			}
-			return nil
-		}
-		if event.Op&fsnotify.Write == fsnotify.Write {
-			if event.Name[len(event.Name)-3:] == ".go" {
+			if ok {
 				if err := w.restart(cmd, event); err != nil {
 					if !errors.Is(err, ErrCmdCompile) {
 						return err
 					}
 				}
 			}
-		}
+			pending = map[string]fsnotify.Event{}
 
-	case err, ok := <-w.watcher.Errors:
-		if !ok {
-			return fmt.Errorf("watcher files changes error: %v", err)
-		}
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return fmt.Errorf("watcher files changes error: %v", err)
+			}
 
+		}
 	}
-	return nil
 }
 
 func (w Watcher) start(cmd *exec.Cmd) error {
-	directories, err := discoverSubDirectories(w.dir)
+	directories, err := w.discoverSubDirectories(w.dir)
 	if err != nil {
 		return err
 	}
 	if err := w.addDirectories(directories...); err != nil {
 		return err
 	}
-	for {
-		if err := w.events(cmd); err != nil {
-			return err
-		}
-	}
+	return w.events(cmd)
 }
 
 func (w Watcher) addDirectories(directories ...string) error {
@@ -163,14 +312,73 @@ func (w Watcher) addDirectories(directories ...string) error {
 	return nil
 }
 
+//qualifyingEvent decides, across every event coalesced into a single
+//debounce window, whether the batch should trigger a restart at all, and
+//picks one representative event for it. A batch restarts as soon as any of
+//its files is not ignored, even if others in the same window are; the
+//representative event prefers a non-.css file so a batch that mixes a .go
+//write with a .css write is not mistaken for a CSS-only reload.
+func (w Watcher) qualifyingEvent(pending map[string]fsnotify.Event) (fsnotify.Event, bool, error) {
+	var chosen fsnotify.Event
+	found := false
+	for _, event := range pending {
+		ignore, err := w.isToIgnoreFile(event.Name)
+		if err != nil {
+			return fsnotify.Event{}, false, err
+		}
+		if ignore {
+			continue
+		}
+		if !found || filepath.Ext(chosen.Name) == ".css" {
+			chosen = event
+			found = true
+		}
+	}
+	return chosen, found, nil
+}
+
 func (w Watcher) restart(cmd *exec.Cmd, event fsnotify.Event) error {
 	ignore, err := w.isToIgnoreFile(event.Name)
 	if err != nil {
 		return err
 	}
-	if !ignore {
-		logrus.Debugf("Modified file: %s\n", event.Name)
-		return w.app.Restart(cmd)
+	if ignore {
+		return nil
+	}
+	logrus.Debugf("Modified file: %s\n", event.Name)
+
+	if w.hooks.PreBuild != nil {
+		if err := w.hooks.PreBuild(event); err != nil {
+			return err
+		}
+	}
+	if err := w.app.Compile(); err != nil {
+		return err
+	}
+	if w.hooks.PostBuild != nil {
+		if err := w.hooks.PostBuild(event); err != nil {
+			return err
+		}
+	}
+	if w.hooks.PreRun != nil {
+		if err := w.hooks.PreRun(event); err != nil {
+			return err
+		}
+	}
+	if err := w.app.Stop(cmd); err != nil {
+		return err
+	}
+	newCmd, err := w.app.Start()
+	if err != nil {
+		return err
+	}
+	*cmd = *newCmd
+
+	if w.liveReload != nil {
+		w.liveReload.Reload(event.Name)
+	}
+	if w.hooks.OnRestart != nil {
+		return w.hooks.OnRestart(event)
 	}
 	return nil
 }
@@ -184,15 +392,22 @@ func contains(list []string, value string) bool {
 	return false
 }
 
-func discoverSubDirectories(baseDir string) ([]string, error) {
+//discoverSubDirectories walks baseDir collecting every subdirectory to watch,
+//pruning dotfile directories, defaultSkipDirs and anything matched by
+//.gowatchignore so they never consume an inotify watch.
+func (w Watcher) discoverSubDirectories(baseDir string) ([]string, error) {
 	directories := []string{}
 	if err := filepath.Walk(baseDir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
-		if info.IsDir() {
-			directories = append(directories, path)
+		if !info.IsDir() {
+			return nil
+		}
+		if path != w.dir && w.shouldSkipDir(path) {
+			return filepath.SkipDir
 		}
+		directories = append(directories, path)
 		return nil
 	}); err != nil {
 		return nil, err
@@ -200,6 +415,21 @@ func discoverSubDirectories(baseDir string) ([]string, error) {
 	return directories, nil
 }
 
+//shouldSkipDir reports whether path should be pruned from the recursive watch
+func (w Watcher) shouldSkipDir(path string) bool {
+	name := filepath.Base(path)
+	if strings.HasPrefix(name, ".") {
+		return true
+	}
+	if contains(defaultSkipDirs, name) {
+		return true
+	}
+	if w.gowatchignore != nil && w.gowatchignore.MatchesPath(w.relativeToDir(path)) {
+		return true
+	}
+	return false
+}
+
 func getCurrentFolderName(dir string) string {
 	folders := strings.Split(dir, "/")
 	currentFolder := folders[len(folders)-1]
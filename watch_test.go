@@ -0,0 +1,50 @@
+package gowatch
+
+import (
+	"testing"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+func TestQualifyingEventSkipsBatchWithOnlyIgnoredFiles(t *testing.T) {
+	w := Watcher{ignore: []string{"*.tmp"}, includeExt: []string{".go"}}
+	pending := map[string]fsnotify.Event{
+		"main.tmp": {Name: "main.tmp", Op: fsnotify.Write},
+	}
+
+	if _, ok, err := w.qualifyingEvent(pending); err != nil || ok {
+		t.Fatalf("expected no qualifying event, got ok=%v err=%v", ok, err)
+	}
+}
+
+func TestQualifyingEventRestartsWhenAnyFileQualifies(t *testing.T) {
+	w := Watcher{ignore: []string{"*.tmp"}, includeExt: []string{".go"}}
+	pending := map[string]fsnotify.Event{
+		"main.tmp": {Name: "main.tmp", Op: fsnotify.Write},
+		"main.go":  {Name: "main.go", Op: fsnotify.Write},
+	}
+
+	event, ok, err := w.qualifyingEvent(pending)
+	if err != nil || !ok {
+		t.Fatalf("expected a qualifying event, got ok=%v err=%v", ok, err)
+	}
+	if event.Name != "main.go" {
+		t.Fatalf("expected main.go as the restart trigger, got %q", event.Name)
+	}
+}
+
+func TestQualifyingEventPrefersNonCSSRepresentative(t *testing.T) {
+	w := Watcher{includeExt: []string{".go", ".css"}}
+	pending := map[string]fsnotify.Event{
+		"style.css": {Name: "style.css", Op: fsnotify.Write},
+		"main.go":   {Name: "main.go", Op: fsnotify.Write},
+	}
+
+	event, ok, err := w.qualifyingEvent(pending)
+	if err != nil || !ok {
+		t.Fatalf("expected a qualifying event, got ok=%v err=%v", ok, err)
+	}
+	if event.Name != "main.go" {
+		t.Fatalf("expected main.go to be preferred over style.css, got %q", event.Name)
+	}
+}